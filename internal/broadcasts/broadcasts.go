@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: MIT
+
+// Package broadcasts implements small fan-out notification channels used to
+// keep things like the HTTP admin dashboard in sync with state owned
+// elsewhere (the tunnel endpoint registry, the members table) without
+// polling.
+package broadcasts
+
+import "sync"
+
+// feedRefBroadcast fans out a payload-free tick to every currently subscribed
+// listener. Slow or gone listeners never block a broadcast: sends are
+// best-effort and dropped if a listener's channel is full.
+type feedRefBroadcast struct {
+	mu   sync.Mutex
+	subs map[chan struct{}]struct{}
+}
+
+func newFeedRefBroadcast() feedRefBroadcast {
+	return feedRefBroadcast{
+		subs: make(map[chan struct{}]struct{}),
+	}
+}
+
+// Subscribe returns a channel that receives a tick when Emit is called, and
+// a function to unsubscribe once the caller is done listening. The channel
+// holds at most one pending tick, so several Emit calls before the listener
+// reads collapse into a single tick.
+func (b *feedRefBroadcast) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	cancel := func() {
+		b.mu.Lock()
+		delete(b.subs, ch)
+		b.mu.Unlock()
+	}
+
+	return ch, cancel
+}
+
+func (b *feedRefBroadcast) emit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// listener hasn't drained the last tick yet, skip it
+		}
+	}
+}
+
+// EndpointsEmitter notifies subscribers whenever the set of connected tunnel
+// endpoints changes.
+type EndpointsEmitter struct {
+	feedRefBroadcast
+}
+
+// NewEndpointsEmitter returns a ready to use EndpointsEmitter.
+func NewEndpointsEmitter() *EndpointsEmitter {
+	return &EndpointsEmitter{newFeedRefBroadcast()}
+}
+
+// Emit notifies subscribers that the endpoint set changed.
+func (e *EndpointsEmitter) Emit() {
+	e.emit()
+}
+
+// AttendantsEmitter notifies subscribers whenever the list of attendants
+// (connected peers shown in the room UI) changes.
+type AttendantsEmitter struct {
+	feedRefBroadcast
+}
+
+// NewAttendantsEmitter returns a ready to use AttendantsEmitter.
+func NewAttendantsEmitter() *AttendantsEmitter {
+	return &AttendantsEmitter{newFeedRefBroadcast()}
+}
+
+// Emit notifies subscribers that the attendants list changed.
+func (e *AttendantsEmitter) Emit() {
+	e.emit()
+}
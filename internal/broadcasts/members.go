@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: MIT
+
+package broadcasts
+
+import (
+	"sync"
+
+	"github.com/ssb-ngi-pointer/go-ssb-room/roomdb"
+	refs "go.mindeco.de/ssb-refs"
+)
+
+// MemberChangeType is the kind of mutation a MemberChange describes.
+type MemberChangeType string
+
+const (
+	MemberAdded       MemberChangeType = "added"
+	MemberRemoved     MemberChangeType = "removed"
+	MemberRoleChanged MemberChangeType = "role-changed"
+)
+
+// MemberChange describes a single mutation of the members table.
+type MemberChange struct {
+	Type MemberChangeType `json:"type"`
+	ID   refs.FeedRef     `json:"id"`
+	Role roomdb.Role      `json:"role"`
+}
+
+// MembersEmitter notifies subscribers whenever a member is added, removed,
+// or has their role changed. Unlike the tick-only EndpointsEmitter and
+// AttendantsEmitter, listeners
+// need the actual change to stream it to clients, so each subscriber gets
+// its own buffered channel of MemberChange values.
+type MembersEmitter struct {
+	mu   sync.Mutex
+	subs map[chan MemberChange]struct{}
+}
+
+// NewMembersEmitter returns a ready to use MembersEmitter.
+func NewMembersEmitter() *MembersEmitter {
+	return &MembersEmitter{
+		subs: make(map[chan MemberChange]struct{}),
+	}
+}
+
+// Subscribe returns a channel that receives every MemberChange emitted from
+// this point on, and a function to unsubscribe once the caller is done
+// listening.
+func (e *MembersEmitter) Subscribe() (<-chan MemberChange, func()) {
+	ch := make(chan MemberChange, 16)
+
+	e.mu.Lock()
+	e.subs[ch] = struct{}{}
+	e.mu.Unlock()
+
+	cancel := func() {
+		e.mu.Lock()
+		delete(e.subs, ch)
+		e.mu.Unlock()
+	}
+
+	return ch, cancel
+}
+
+// Emit notifies every subscriber of change. A subscriber whose buffer is
+// full is skipped rather than blocking the caller that mutated the table.
+func (e *MembersEmitter) Emit(change MemberChange) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for ch := range e.subs {
+		select {
+		case ch <- change:
+		default:
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ssb-ngi-pointer/go-ssb-room/roomdb"
+	refs "go.mindeco.de/ssb-refs"
+)
+
+// Errors returned by the access checks below, so that clients can surface a
+// specific message instead of a generic "access denied".
+var (
+	errCallerNotMember = errors.New("room: caller not a member")
+	errTargetNotMember = errors.New("room: target not a member")
+	errRoomClosed      = errors.New("room: room closed")
+	errAdminOnly       = errors.New("room: admins only")
+)
+
+// checkTunnelAccess enforces the room's privacy mode for a tunnel.connect
+// call from caller to target. It is the single place that reasons about who
+// may reach whom through this room. Sources that list membership, such as
+// room.members, use checkEnumerateAccess instead.
+func (h *Handler) checkTunnelAccess(ctx context.Context, caller, target refs.FeedRef) error {
+	mode, err := h.config.GetPrivacyMode(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch mode {
+	case roomdb.ModeOpen:
+		return nil
+
+	case roomdb.ModeCommunity:
+		// the room is open to the public, but a tunnel may only be formed
+		// towards a member
+		if _, err := h.membersDB.GetByFeed(ctx, target); err != nil {
+			return errTargetNotMember
+		}
+		return nil
+
+	case roomdb.ModeRestricted:
+		if _, err := h.membersDB.GetByFeed(ctx, caller); err != nil {
+			return errCallerNotMember
+		}
+		if _, err := h.membersDB.GetByFeed(ctx, target); err != nil {
+			return errTargetNotMember
+		}
+		return nil
+
+	default:
+		return errRoomClosed
+	}
+}
+
+// checkEnumerateAccess enforces the room's privacy mode for sources that let
+// a caller list room membership or presence information. Only ModeOpen lets
+// a non-member enumerate.
+func (h *Handler) checkEnumerateAccess(ctx context.Context, caller refs.FeedRef) error {
+	mode, err := h.config.GetPrivacyMode(ctx)
+	if err != nil {
+		return err
+	}
+
+	if mode == roomdb.ModeOpen {
+		return nil
+	}
+
+	if _, err := h.membersDB.GetByFeed(ctx, caller); err != nil {
+		return errCallerNotMember
+	}
+
+	return nil
+}
+
+// checkMembershipChangesAccess enforces who may subscribe to
+// room.membershipChanges: admins always may, and other members may too once
+// the room's privacy mode is opened up.
+func (h *Handler) checkMembershipChangesAccess(ctx context.Context, caller refs.FeedRef) error {
+	member, err := h.membersDB.GetByFeed(ctx, caller)
+	if err != nil {
+		return errCallerNotMember
+	}
+
+	if member.Role == roomdb.RoleAdmin {
+		return nil
+	}
+
+	mode, err := h.config.GetPrivacyMode(ctx)
+	if err != nil {
+		return err
+	}
+
+	if mode != roomdb.ModeOpen {
+		return errAdminOnly
+	}
+
+	return nil
+}
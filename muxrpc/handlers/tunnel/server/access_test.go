@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ssb-ngi-pointer/go-ssb-room/roomdb"
+	refs "go.mindeco.de/ssb-refs"
+)
+
+// testMembers is a minimal stand-in for roomdb.MembersService that only
+// knows about the feeds it was seeded with, each holding the role it was
+// given (roomdb.RoleMember by default).
+type testMembers struct {
+	byFeed map[string]roomdb.Member
+	nextID int64
+}
+
+// testMemberRole pairs a feed with the role it should hold, for seeding
+// testMembers with something other than the roomdb.RoleMember default, e.g.
+// an admin for the checkMembershipChangesAccess bypass.
+type testMemberRole struct {
+	ref  refs.FeedRef
+	role roomdb.Role
+}
+
+func newTestMembers(known ...refs.FeedRef) *testMembers {
+	roles := make([]testMemberRole, len(known))
+	for i, k := range known {
+		roles[i] = testMemberRole{ref: k, role: roomdb.RoleMember}
+	}
+	return newTestMembersWithRoles(roles...)
+}
+
+func newTestMembersWithRoles(roles ...testMemberRole) *testMembers {
+	m := &testMembers{byFeed: make(map[string]roomdb.Member)}
+	for _, tc := range roles {
+		m.nextID++
+		m.byFeed[tc.ref.Ref()] = roomdb.Member{ID: m.nextID, PubKey: tc.ref, Role: tc.role}
+	}
+	return m
+}
+
+func (m *testMembers) GetByFeed(ctx context.Context, ref refs.FeedRef) (roomdb.Member, error) {
+	member, ok := m.byFeed[ref.Ref()]
+	if !ok {
+		return roomdb.Member{}, roomdb.ErrNotFound
+	}
+	return member, nil
+}
+
+// List is also needed by the room.members source, so it has to exist
+// here even though none of the access-check tests in this file use it.
+func (m *testMembers) List(ctx context.Context) ([]roomdb.Member, error) {
+	all := make([]roomdb.Member, 0, len(m.byFeed))
+	for _, member := range m.byFeed {
+		all = append(all, member)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	return all, nil
+}
+
+// testConfig is a minimal stand-in for roomdb.Config that just returns a
+// fixed privacy mode.
+type testConfig struct {
+	mode roomdb.PrivacyMode
+}
+
+func (c *testConfig) GetPrivacyMode(ctx context.Context) (roomdb.PrivacyMode, error) {
+	return c.mode, nil
+}
+
+func mustFeed(t *testing.T, ref string) refs.FeedRef {
+	t.Helper()
+	fr, err := refs.ParseFeedRef(ref)
+	require.NoError(t, err)
+	return *fr
+}
+
+func TestCheckTunnelAccess(t *testing.T) {
+	caller := mustFeed(t, "@YWRtaW5hZG1pbmFkbWluYWRtaW5hZG1pbmFkbWluYWQ=.ed25519")
+	target := mustFeed(t, "@bWVtYmVybWVtYmVybWVtYmVybWVtYmVybWVtYmVybWU=.ed25519")
+
+	cases := []struct {
+		name    string
+		mode    roomdb.PrivacyMode
+		members *testMembers
+		wantErr error
+	}{
+		{"open allows strangers", roomdb.ModeOpen, newTestMembers(), nil},
+		{"community requires target membership", roomdb.ModeCommunity, newTestMembers(), errTargetNotMember},
+		{"community allows member target", roomdb.ModeCommunity, newTestMembers(target), nil},
+		{"restricted requires both", roomdb.ModeRestricted, newTestMembers(), errCallerNotMember},
+		{"restricted requires target too", roomdb.ModeRestricted, newTestMembers(caller), errTargetNotMember},
+		{"restricted allows members", roomdb.ModeRestricted, newTestMembers(caller, target), nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := Handler{
+				config:    &testConfig{mode: tc.mode},
+				membersDB: tc.members,
+			}
+
+			err := h.checkTunnelAccess(context.Background(), caller, target)
+			require.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestCheckEnumerateAccess(t *testing.T) {
+	caller := refs.FeedRef{}
+
+	h := Handler{
+		config:    &testConfig{mode: roomdb.ModeOpen},
+		membersDB: newTestMembers(),
+	}
+	require.NoError(t, h.checkEnumerateAccess(context.Background(), caller))
+
+	h.config = &testConfig{mode: roomdb.ModeRestricted}
+	require.Equal(t, errCallerNotMember, h.checkEnumerateAccess(context.Background(), caller))
+
+	h.membersDB = newTestMembers(caller)
+	require.NoError(t, h.checkEnumerateAccess(context.Background(), caller))
+}
+
+func TestCheckMembershipChangesAccess(t *testing.T) {
+	caller := refs.FeedRef{}
+
+	h := Handler{
+		config:    &testConfig{mode: roomdb.ModeRestricted},
+		membersDB: newTestMembers(),
+	}
+	require.Equal(t, errCallerNotMember, h.checkMembershipChangesAccess(context.Background(), caller))
+
+	// a plain member can't subscribe while the room is restricted
+	h.membersDB = newTestMembers(caller)
+	require.Equal(t, errAdminOnly, h.checkMembershipChangesAccess(context.Background(), caller))
+
+	// opening the room up lets members subscribe too
+	h.config = &testConfig{mode: roomdb.ModeOpen}
+	require.NoError(t, h.checkMembershipChangesAccess(context.Background(), caller))
+
+	// an admin is let through even in a restricted room
+	h.config = &testConfig{mode: roomdb.ModeRestricted}
+	h.membersDB = newTestMembersWithRoles(testMemberRole{ref: caller, role: roomdb.RoleAdmin})
+	require.NoError(t, h.checkMembershipChangesAccess(context.Background(), caller))
+}
@@ -49,6 +49,10 @@ func (h *Handler) connect(ctx context.Context, req *muxrpc.Request, peerSrc *mux
 		return err
 	}
 
+	if err := h.checkTunnelAccess(ctx, *caller, arg.Target); err != nil {
+		return err
+	}
+
 	// see if we have and endpoint for the target
 
 	edp, has := h.state.Has(arg.Target)
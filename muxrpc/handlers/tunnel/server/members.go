@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"go.cryptoscope.co/muxrpc/v2"
+
+	"github.com/ssb-ngi-pointer/go-ssb-room/internal/network"
+	"github.com/ssb-ngi-pointer/go-ssb-room/roomdb"
+	refs "go.mindeco.de/ssb-refs"
+)
+
+// membersArg is the optional argument to the members source, used to page
+// through large rooms instead of dumping the whole member list at once.
+type membersArg struct {
+	Limit  int   `json:"limit"`
+	Cursor int64 `json:"cursor"`
+}
+
+// memberEntry is what gets streamed down the wire for each member.
+type memberEntry struct {
+	ID refs.FeedRef `json:"id"`
+}
+
+// members streams every member of the room, regardless of whether they are
+// currently connected, unlike the attendants source which only lists live
+// tunnel endpoints.
+func (h *Handler) members(ctx context.Context, req *muxrpc.Request, snk *muxrpc.ByteSink) error {
+	defer snk.Close()
+
+	caller, err := network.GetFeedRefFromAddr(req.RemoteAddr())
+	if err != nil {
+		return err
+	}
+
+	return h.streamMembers(ctx, *caller, req.RawArgs, snk)
+}
+
+// streamMembers does the actual work behind the members source: it decodes
+// rawArgs, enforces the privacy mode for caller, and writes one JSON-encoded
+// memberEntry per page entry to w. It's split out from members() so the
+// muxrpc-specific plumbing (resolving the caller's feed from the connection,
+// closing the sink) stays a thin wrapper that can be tested against a plain
+// io.Writer instead of a live muxrpc connection.
+func (h *Handler) streamMembers(ctx context.Context, caller refs.FeedRef, rawArgs json.RawMessage, w io.Writer) error {
+	var args []membersArg
+	if err := json.Unmarshal(rawArgs, &args); err != nil {
+		return fmt.Errorf("members: invalid arguments: %w", err)
+	}
+
+	var arg membersArg
+	if len(args) == 1 {
+		arg = args[0]
+	}
+
+	if err := h.checkEnumerateAccess(ctx, caller); err != nil {
+		return err
+	}
+
+	all, err := h.membersDB.List(ctx)
+	if err != nil {
+		return fmt.Errorf("members: failed to list members: %w", err)
+	}
+
+	page := paginateMembers(all, arg.Cursor, arg.Limit)
+
+	enc := json.NewEncoder(w)
+	for _, m := range page {
+		if err := enc.Encode(memberEntry{ID: m.PubKey}); err != nil {
+			return fmt.Errorf("members: failed to marshal entry: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// paginateMembers returns the members with an ID greater than cursor, capped
+// at limit entries (a non-positive limit means "no cap"). Members are
+// expected to already be sorted by ID, which is how Members.List returns them.
+func paginateMembers(all []roomdb.Member, cursor int64, limit int) []roomdb.Member {
+	var page []roomdb.Member
+	for _, m := range all {
+		if int64(m.ID) <= cursor {
+			continue
+		}
+
+		if limit > 0 && len(page) >= limit {
+			break
+		}
+
+		page = append(page, m)
+	}
+
+	return page
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ssb-ngi-pointer/go-ssb-room/roomdb"
+	refs "go.mindeco.de/ssb-refs"
+)
+
+func TestPaginateMembers(t *testing.T) {
+	r := require.New(t)
+
+	all := []roomdb.Member{
+		{ID: 1, Role: roomdb.RoleAdmin, PubKey: refs.FeedRef{}},
+		{ID: 2, Role: roomdb.RoleMember, PubKey: refs.FeedRef{}},
+		{ID: 3, Role: roomdb.RoleMember, PubKey: refs.FeedRef{}},
+	}
+
+	// no cursor, no limit: everything comes back
+	r.Len(paginateMembers(all, 0, 0), 3)
+
+	// cursor skips everything up to and including that ID
+	page := paginateMembers(all, 1, 0)
+	r.Len(page, 2)
+	r.EqualValues(2, page[0].ID)
+
+	// limit caps the page size
+	page = paginateMembers(all, 0, 2)
+	r.Len(page, 2)
+	r.EqualValues(1, page[0].ID)
+	r.EqualValues(2, page[1].ID)
+}
+
+// TestHandlerStreamMembers drives streamMembers end to end: decoding
+// rawArgs, enforcing the privacy mode, paginating, and encoding the result,
+// and checks the NDJSON stream it emits.
+func TestHandlerStreamMembers(t *testing.T) {
+	r := require.New(t)
+
+	caller := mustFeed(t, "@YWRtaW5hZG1pbmFkbWluYWRtaW5hZG1pbmFkbWluYWQ=.ed25519")
+	m1 := mustFeed(t, "@bWVtYmVybWVtYmVybWVtYmVybWVtYmVybWVtYmVybWU=.ed25519")
+	m2 := mustFeed(t, "@b3RoZXJvdGhlcm90aGVyb3RoZXJvdGhlcm90aGVyb3Q=.ed25519")
+
+	h := Handler{
+		config:    &testConfig{mode: roomdb.ModeOpen},
+		membersDB: newTestMembers(m1, m2),
+	}
+
+	var buf bytes.Buffer
+	r.NoError(h.streamMembers(context.Background(), caller, json.RawMessage(`[{"limit":1}]`), &buf))
+
+	dec := json.NewDecoder(&buf)
+	var got []memberEntry
+	for dec.More() {
+		var entry memberEntry
+		r.NoError(dec.Decode(&entry))
+		got = append(got, entry)
+	}
+	r.Len(got, 1)
+	r.True(got[0].ID.Equal(&m1), "expected the first page entry to be the lowest-ID member")
+
+	// a restricted room refuses a non-member caller before the list is even read
+	h.config = &testConfig{mode: roomdb.ModeRestricted}
+	buf.Reset()
+	err := h.streamMembers(context.Background(), caller, json.RawMessage(`[]`), &buf)
+	r.Equal(errCallerNotMember, err)
+	r.Zero(buf.Len())
+}
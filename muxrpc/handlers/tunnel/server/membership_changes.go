@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.cryptoscope.co/muxrpc/v2"
+
+	"github.com/ssb-ngi-pointer/go-ssb-room/internal/network"
+)
+
+// membershipChanges streams a broadcasts.MemberChange for every member
+// added, removed, or re-roled from this point on, so clients can keep a
+// cached member list fresh without polling room.members.
+func (h *Handler) membershipChanges(ctx context.Context, req *muxrpc.Request, snk *muxrpc.ByteSink) error {
+	defer snk.Close()
+
+	caller, err := network.GetFeedRefFromAddr(req.RemoteAddr())
+	if err != nil {
+		return err
+	}
+
+	if err := h.checkMembershipChangesAccess(ctx, *caller); err != nil {
+		return err
+	}
+
+	changes, cancel := h.memberChanges.Subscribe()
+	defer cancel()
+
+	enc := json.NewEncoder(snk)
+	for {
+		select {
+		case change := <-changes:
+			if err := enc.Encode(change); err != nil {
+				return fmt.Errorf("membershipChanges: failed to marshal event: %w", err)
+			}
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
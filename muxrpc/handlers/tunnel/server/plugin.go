@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	kitlog "github.com/go-kit/kit/log"
+	"go.cryptoscope.co/muxrpc/v2"
+	"go.cryptoscope.co/muxrpc/v2/typemux"
+
+	"github.com/ssb-ngi-pointer/go-ssb-room/internal/broadcasts"
+	"github.com/ssb-ngi-pointer/go-ssb-room/roomdb"
+	"github.com/ssb-ngi-pointer/go-ssb-room/roomstate"
+	refs "go.mindeco.de/ssb-refs"
+)
+
+// Handler implements the muxrpc tunnel plugin, along with the room-scoped
+// sources (room.members, room.membershipChanges) that live alongside it.
+type Handler struct {
+	self   refs.FeedRef
+	logger kitlog.Logger
+
+	// state is shared with the HTTP admin dashboard so both can reason
+	// about who's currently connected without duplicating that bookkeeping.
+	state *roomstate.Manager
+
+	config roomdb.Config
+
+	// membersDB is named distinctly from the members() source handler
+	// method in members.go, since Go won't let a type have both a field and
+	// a method of the same name.
+	membersDB roomdb.MembersService
+
+	memberChanges *broadcasts.MembersEmitter
+}
+
+// New constructs a Handler and registers its methods on mux.
+func New(
+	logger kitlog.Logger,
+	self refs.FeedRef,
+	state *roomstate.Manager,
+	config roomdb.Config,
+	members roomdb.MembersService,
+	memberChanges *broadcasts.MembersEmitter,
+	mux *typemux.HandlerMux,
+) *Handler {
+	h := &Handler{
+		self:          self,
+		logger:        logger,
+		state:         state,
+		config:        config,
+		membersDB:     members,
+		memberChanges: memberChanges,
+	}
+
+	mux.RegisterDuplex(muxrpc.Method{"tunnel", "connect"}, typemux.DuplexFunc(h.connect))
+	mux.RegisterSource(muxrpc.Method{"room", "members"}, typemux.SourceFunc(h.members))
+	mux.RegisterSource(muxrpc.Method{"room", "membershipChanges"}, typemux.SourceFunc(h.membershipChanges))
+
+	return h
+}
@@ -0,0 +1,10 @@
+package roomdb
+
+// ErrLastAdmin is returned when an operation would remove or demote the
+// last remaining admin of the room, which would leave nobody able to manage
+// membership.
+type ErrLastAdmin struct{}
+
+func (ErrLastAdmin) Error() string {
+	return "roomdb: can't remove or demote the last admin"
+}
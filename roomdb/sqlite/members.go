@@ -7,6 +7,7 @@ import (
 
 	"github.com/friendsofgo/errors"
 	"github.com/mattn/go-sqlite3"
+	"github.com/ssb-ngi-pointer/go-ssb-room/internal/broadcasts"
 	"github.com/ssb-ngi-pointer/go-ssb-room/roomdb"
 	"github.com/ssb-ngi-pointer/go-ssb-room/roomdb/sqlite/models"
 	"github.com/volatiletech/sqlboiler/v4/boil"
@@ -19,6 +20,25 @@ var _ roomdb.MembersService = (*Members)(nil)
 
 type Members struct {
 	db *sql.DB
+
+	// changes is notified whenever a member is added, removed or has its
+	// role changed. It may be nil, in which case mutations simply aren't
+	// observed by anyone (e.g. in tests that don't care about them).
+	changes *broadcasts.MembersEmitter
+}
+
+// NewMembers returns a Members service backed by db. changes is notified of
+// every mutation the service performs; pass nil if nothing needs to observe
+// them.
+func NewMembers(db *sql.DB, changes *broadcasts.MembersEmitter) Members {
+	return Members{db: db, changes: changes}
+}
+
+// emit notifies m.changes of a mutation, if anyone is wired up to receive it.
+func (m Members) emit(change broadcasts.MemberChange) {
+	if m.changes != nil {
+		m.changes.Emit(change)
+	}
 }
 
 func (m Members) Add(ctx context.Context, pubKey refs.FeedRef, role roomdb.Role) (int64, error) {
@@ -31,6 +51,9 @@ func (m Members) Add(ctx context.Context, pubKey refs.FeedRef, role roomdb.Role)
 	if err != nil {
 		return -1, err
 	}
+
+	m.emit(broadcasts.MemberChange{Type: broadcasts.MemberAdded, ID: pubKey, Role: role})
+
 	return newID, nil
 }
 
@@ -91,9 +114,10 @@ func (m Members) GetByFeed(ctx context.Context, h refs.FeedRef) (roomdb.Member,
 	}, nil
 }
 
-// List returns a list of all the feeds.
+// List returns a list of all the feeds, ordered by ID so that callers (like
+// the room.members cursor) can rely on a stable order.
 func (m Members) List(ctx context.Context) ([]roomdb.Member, error) {
-	all, err := models.Members().All(ctx, m.db)
+	all, err := models.Members(qm.OrderBy("id asc")).All(ctx, m.db)
 	if err != nil {
 		return nil, err
 	}
@@ -118,37 +142,69 @@ func (m Members) Count(ctx context.Context) (uint, error) {
 
 // RemoveFeed removes the feed from the list.
 func (m Members) RemoveFeed(ctx context.Context, r refs.FeedRef) error {
-	entry, err := models.Members(qm.Where("pub_key = ?", r.Ref())).One(ctx, m.db)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return roomdb.ErrNotFound
+	var removedRole roomdb.Role
+
+	err := transact(m.db, func(tx *sql.Tx) error {
+		entry, err := models.Members(qm.Where("pub_key = ?", r.Ref())).One(ctx, tx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return roomdb.ErrNotFound
+			}
+			return err
+		}
+
+		removedRole = roomdb.Role(entry.Role)
+		if err := m.guardLastAdmin(ctx, tx, entry.ID, removedRole); err != nil {
+			return err
 		}
-		return err
-	}
 
-	_, err = entry.Delete(ctx, m.db)
+		_, err = entry.Delete(ctx, tx)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
+	m.emit(broadcasts.MemberChange{Type: broadcasts.MemberRemoved, ID: r, Role: removedRole})
+
 	return nil
 }
 
 // RemoveID removes the feed from the list.
 func (m Members) RemoveID(ctx context.Context, id int64) error {
-	entry, err := models.FindMember(ctx, m.db, id)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return roomdb.ErrNotFound
+	var removed models.Member
+
+	err := transact(m.db, func(tx *sql.Tx) error {
+		entry, err := models.FindMember(ctx, tx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return roomdb.ErrNotFound
+			}
+			return err
 		}
-		return err
-	}
 
-	_, err = entry.Delete(ctx, m.db)
+		if err := m.guardLastAdmin(ctx, tx, entry.ID, roomdb.Role(entry.Role)); err != nil {
+			return err
+		}
+
+		_, err = entry.Delete(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		removed = *entry
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
+	m.emit(broadcasts.MemberChange{
+		Type: broadcasts.MemberRemoved,
+		ID:   removed.PubKey.FeedRef,
+		Role: roomdb.Role(removed.Role),
+	})
+
 	return nil
 }
 
@@ -158,8 +214,49 @@ func (m Members) SetRole(ctx context.Context, id int64, r roomdb.Role) error {
 		return err
 	}
 
-	return transact(m.db, func(tx *sql.Tx) error {
-		m, err := models.FindMember(ctx, tx, id)
+	var pubKey refs.FeedRef
+
+	err := transact(m.db, func(tx *sql.Tx) error {
+		entry, err := models.FindMember(ctx, tx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return roomdb.ErrNotFound
+			}
+			return err
+		}
+
+		// only guard against losing the last admin when this is actually a
+		// demotion away from admin; promoting a member is always fine, even
+		// if they are currently the only admin
+		if roomdb.Role(entry.Role) == roomdb.RoleAdmin && r != roomdb.RoleAdmin {
+			if err := m.guardLastAdmin(ctx, tx, id, roomdb.RoleAdmin); err != nil {
+				return err
+			}
+		}
+
+		entry.Role = int64(r)
+		pubKey = entry.PubKey.FeedRef
+		_, err = entry.Update(ctx, tx, boil.Whitelist("role"))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	m.emit(broadcasts.MemberChange{Type: broadcasts.MemberRoleChanged, ID: pubKey, Role: r})
+
+	return nil
+}
+
+// Transfer atomically swaps the roles of the from and to members, which is
+// meant to be used for admin handover: the incoming admin is promoted and
+// the outgoing admin keeps the other member's previous role, all within one
+// transaction so the room is never without an admin in between.
+func (m Members) Transfer(ctx context.Context, from, to int64) error {
+	var fromChange, toChange broadcasts.MemberChange
+
+	err := transact(m.db, func(tx *sql.Tx) error {
+		fromEntry, err := models.FindMember(ctx, tx, from)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				return roomdb.ErrNotFound
@@ -167,21 +264,57 @@ func (m Members) SetRole(ctx context.Context, id int64, r roomdb.Role) error {
 			return err
 		}
 
-		// find the number of other admins
-		admins, err := models.Members(
-			qm.Where("id != ?", id),
-			qm.Where("role = ?", roomdb.RoleAdmin),
-		).Count(ctx, tx)
+		toEntry, err := models.FindMember(ctx, tx, to)
 		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return roomdb.ErrNotFound
+			}
 			return err
 		}
 
-		if admins < 1 {
-			return fmt.Errorf("need at least one other admin")
+		fromEntry.Role, toEntry.Role = toEntry.Role, fromEntry.Role
+
+		if _, err := fromEntry.Update(ctx, tx, boil.Whitelist("role")); err != nil {
+			return err
+		}
+		if _, err := toEntry.Update(ctx, tx, boil.Whitelist("role")); err != nil {
+			return err
 		}
 
-		m.Role = int64(r)
-		_, err = m.Update(ctx, tx, boil.Whitelist("role"))
-		return err
+		fromChange = broadcasts.MemberChange{Type: broadcasts.MemberRoleChanged, ID: fromEntry.PubKey.FeedRef, Role: roomdb.Role(fromEntry.Role)}
+		toChange = broadcasts.MemberChange{Type: broadcasts.MemberRoleChanged, ID: toEntry.PubKey.FeedRef, Role: roomdb.Role(toEntry.Role)}
+
+		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	m.emit(fromChange)
+	m.emit(toChange)
+
+	return nil
+}
+
+// guardLastAdmin returns roomdb.ErrLastAdmin if id is currently the only
+// admin and role is roomdb.RoleAdmin, i.e. removing or demoting id would
+// leave the room without an admin.
+func (Members) guardLastAdmin(ctx context.Context, tx *sql.Tx, id int64, role roomdb.Role) error {
+	if role != roomdb.RoleAdmin {
+		return nil
+	}
+
+	otherAdmins, err := models.Members(
+		qm.Where("id != ?", id),
+		qm.Where("role = ?", roomdb.RoleAdmin),
+	).Count(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if otherAdmins == 0 {
+		return roomdb.ErrLastAdmin{}
+	}
+
+	return nil
 }
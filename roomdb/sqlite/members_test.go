@@ -0,0 +1,148 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ssb-ngi-pointer/go-ssb-room/internal/broadcasts"
+	"github.com/ssb-ngi-pointer/go-ssb-room/roomdb"
+	refs "go.mindeco.de/ssb-refs"
+)
+
+func newTestMembersDB(t *testing.T) Members {
+	t.Helper()
+	return newTestMembersDBWithEmitter(t, nil)
+}
+
+func newTestMembersDBWithEmitter(t *testing.T, changes *broadcasts.MembersEmitter) Members {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+
+	_, err = db.Exec(`create table members (
+		id integer primary key autoincrement,
+		pub_key text not null unique,
+		role integer not null
+	)`)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { db.Close() })
+
+	return NewMembers(db, changes)
+}
+
+func mkFeed(t *testing.T, ref string) refs.FeedRef {
+	t.Helper()
+	fr, err := refs.ParseFeedRef(ref)
+	require.NoError(t, err)
+	return *fr
+}
+
+func TestMembersSetRoleLastAdmin(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	m := newTestMembersDB(t)
+
+	admin := mkFeed(t, "@YWRtaW5hZG1pbmFkbWluYWRtaW5hZG1pbmFkbWluYWQ=.ed25519")
+	adminID, err := m.Add(ctx, admin, roomdb.RoleAdmin)
+	r.NoError(err)
+
+	// demoting the sole admin is rejected
+	err = m.SetRole(ctx, adminID, roomdb.RoleMember)
+	r.ErrorAs(err, new(roomdb.ErrLastAdmin))
+
+	member := mkFeed(t, "@bWVtYmVybWVtYmVybWVtYmVybWVtYmVybWVtYmVybWU=.ed25519")
+	memberID, err := m.Add(ctx, member, roomdb.RoleMember)
+	r.NoError(err)
+
+	// promoting a member is always allowed, even with a single existing admin
+	r.NoError(m.SetRole(ctx, memberID, roomdb.RoleAdmin))
+
+	// now that there are two admins, demoting the original one is fine
+	r.NoError(m.SetRole(ctx, adminID, roomdb.RoleMember))
+}
+
+func TestMembersRemoveLastAdmin(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	m := newTestMembersDB(t)
+
+	admin := mkFeed(t, "@YWRtaW5hZG1pbmFkbWluYWRtaW5hZG1pbmFkbWluYWQ=.ed25519")
+	adminID, err := m.Add(ctx, admin, roomdb.RoleAdmin)
+	r.NoError(err)
+
+	err = m.RemoveID(ctx, adminID)
+	r.ErrorAs(err, new(roomdb.ErrLastAdmin))
+
+	err = m.RemoveFeed(ctx, admin)
+	r.ErrorAs(err, new(roomdb.ErrLastAdmin))
+
+	other := mkFeed(t, "@b3RoZXJvdGhlcm90aGVyb3RoZXJvdGhlcm90aGVyb3Q=.ed25519")
+	_, err = m.Add(ctx, other, roomdb.RoleAdmin)
+	r.NoError(err)
+
+	// with two admins present, removing one is fine
+	r.NoError(m.RemoveID(ctx, adminID))
+}
+
+func TestMembersTransfer(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	m := newTestMembersDB(t)
+
+	admin := mkFeed(t, "@YWRtaW5hZG1pbmFkbWluYWRtaW5hZG1pbmFkbWluYWQ=.ed25519")
+	adminID, err := m.Add(ctx, admin, roomdb.RoleAdmin)
+	r.NoError(err)
+
+	member := mkFeed(t, "@bWVtYmVybWVtYmVybWVtYmVybWVtYmVybWVtYmVybWU=.ed25519")
+	memberID, err := m.Add(ctx, member, roomdb.RoleMember)
+	r.NoError(err)
+
+	r.NoError(m.Transfer(ctx, adminID, memberID))
+
+	oldAdmin, err := m.GetByID(ctx, adminID)
+	r.NoError(err)
+	r.Equal(roomdb.RoleMember, oldAdmin.Role)
+
+	newAdmin, err := m.GetByID(ctx, memberID)
+	r.NoError(err)
+	r.Equal(roomdb.RoleAdmin, newAdmin.Role)
+}
+
+func TestMembersEmitsChanges(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	emitter := broadcasts.NewMembersEmitter()
+	ch, cancel := emitter.Subscribe()
+	defer cancel()
+
+	m := newTestMembersDBWithEmitter(t, emitter)
+
+	admin := mkFeed(t, "@YWRtaW5hZG1pbmFkbWluYWRtaW5hZG1pbmFkbWluYWQ=.ed25519")
+	adminID, err := m.Add(ctx, admin, roomdb.RoleAdmin)
+	r.NoError(err)
+
+	added := <-ch
+	r.Equal(broadcasts.MemberAdded, added.Type)
+	r.Equal(roomdb.RoleAdmin, added.Role)
+
+	member := mkFeed(t, "@bWVtYmVybWVtYmVybWVtYmVybWVtYmVybWVtYmVybWU=.ed25519")
+	memberID, err := m.Add(ctx, member, roomdb.RoleMember)
+	r.NoError(err)
+	<-ch // drain the add event for member
+
+	r.NoError(m.SetRole(ctx, memberID, roomdb.RoleAdmin))
+	changed := <-ch
+	r.Equal(broadcasts.MemberRoleChanged, changed.Type)
+	r.Equal(roomdb.RoleAdmin, changed.Role)
+
+	r.NoError(m.RemoveID(ctx, adminID))
+	removed := <-ch
+	r.Equal(broadcasts.MemberRemoved, removed.Type)
+}
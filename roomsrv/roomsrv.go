@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: MIT
+
+// Package roomsrv wires together the roomdb, the muxrpc tunnel plugin, and
+// the HTTP admin dashboard.
+package roomsrv
+
+import (
+	"database/sql"
+
+	kitlog "github.com/go-kit/kit/log"
+	"go.cryptoscope.co/muxrpc/v2/typemux"
+
+	"github.com/ssb-ngi-pointer/go-ssb-room/internal/broadcasts"
+	tunnelserver "github.com/ssb-ngi-pointer/go-ssb-room/muxrpc/handlers/tunnel/server"
+	"github.com/ssb-ngi-pointer/go-ssb-room/roomdb"
+	"github.com/ssb-ngi-pointer/go-ssb-room/roomdb/sqlite"
+	"github.com/ssb-ngi-pointer/go-ssb-room/roomstate"
+	"github.com/ssb-ngi-pointer/go-ssb-room/web/handlers"
+	refs "go.mindeco.de/ssb-refs"
+)
+
+// New builds the members service, wired up to fire a MembersEmitter on
+// every mutation, and the shared roomstate.Manager. The muxrpc tunnel plugin
+// gets both; the HTTP admin dashboard handlers get the Manager only. Neither
+// keeps its own copy of who's currently connected.
+//
+// Besides the dashboard handlers, New returns the pieces it wired together
+// internally (state, members and memberChanges) so callers that need to
+// reach into them directly -- tests chief among them -- don't have to
+// duplicate the wiring themselves.
+func New(
+	logger kitlog.Logger,
+	self refs.FeedRef,
+	db *sql.DB,
+	config roomdb.Config,
+	mux *typemux.HandlerMux,
+) (dashboard *handlers.Handlers, state *roomstate.Manager, members roomdb.MembersService, memberChanges *broadcasts.MembersEmitter) {
+	memberChanges = broadcasts.NewMembersEmitter()
+	members = sqlite.NewMembers(db, memberChanges)
+
+	state = roomstate.NewManager()
+
+	tunnelserver.New(logger, self, state, config, members, memberChanges, mux)
+
+	dashboard = handlers.New(logger, state)
+
+	return dashboard, state, members, memberChanges
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: MIT
+
+package roomsrv
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+	"go.cryptoscope.co/muxrpc/v2/typemux"
+
+	"github.com/ssb-ngi-pointer/go-ssb-room/roomdb"
+	refs "go.mindeco.de/ssb-refs"
+)
+
+// TestNewWiresSharedState checks that New returns a dashboard and a working
+// roomstate.Manager (a registered peer shows up in its list), and that the
+// members service ends up wired to fire its MembersEmitter.
+func TestNewWiresSharedState(t *testing.T) {
+	r := require.New(t)
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	r.NoError(err)
+	defer db.Close()
+
+	_, err = db.Exec(`create table members (
+		id integer primary key autoincrement,
+		pub_key text not null unique,
+		role integer not null
+	)`)
+	r.NoError(err)
+
+	var mux typemux.HandlerMux
+	dashboard, state, members, memberChanges := New(kitlog.NewNopLogger(), refs.FeedRef{}, db, nil, &mux)
+	r.NotNil(dashboard)
+
+	// a peer registered on the shared state shows up in its list
+	peer := mustFeedRef(t, "@bWVtYmVybWVtYmVybWVtYmVybWVtYmVybWVtYmVybWU=.ed25519")
+	state.Register(peer, nil)
+
+	r.Len(state.AsList(), 1)
+	r.True(state.AsList()[0].Equal(&peer))
+
+	// the members service actually fires memberChanges on mutation
+	changes, cancel := memberChanges.Subscribe()
+	defer cancel()
+
+	_, err = members.Add(context.Background(), peer, roomdb.RoleMember)
+	r.NoError(err)
+
+	select {
+	case change := <-changes:
+		r.Equal(peer.Ref(), change.ID.Ref())
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a memberChanges event")
+	}
+}
+
+func mustFeedRef(t *testing.T, ref string) refs.FeedRef {
+	t.Helper()
+	fr, err := refs.ParseFeedRef(ref)
+	require.NoError(t, err)
+	return *fr
+}
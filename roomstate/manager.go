@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: MIT
+
+// Package roomstate tracks which peers currently hold a tunnel endpoint with
+// this room server. It is shared between the muxrpc tunnel plugin, which
+// registers and consults endpoints while wiring connections, and the HTTP
+// admin dashboard, which wants to render a live view of who is connected
+// without having to duplicate that bookkeeping.
+package roomstate
+
+import (
+	"sync"
+
+	"go.cryptoscope.co/muxrpc/v2"
+	"go.mindeco.de/ssb-refs"
+
+	"github.com/ssb-ngi-pointer/go-ssb-room/internal/broadcasts"
+)
+
+// Endpoint is a single connected peer and the muxrpc endpoint used to reach
+// them.
+type Endpoint struct {
+	Ref refs.FeedRef
+
+	muxrpc.Endpoint
+}
+
+// Manager owns the set of currently connected tunnel endpoints. The zero
+// value is not usable, construct one with NewManager.
+type Manager struct {
+	mu        sync.Mutex
+	endpoints map[string]Endpoint
+
+	endpointsEmitter  *broadcasts.EndpointsEmitter
+	attendantsEmitter *broadcasts.AttendantsEmitter
+}
+
+// NewManager returns a ready to use Manager with empty state.
+func NewManager() *Manager {
+	return &Manager{
+		endpoints:         make(map[string]Endpoint),
+		endpointsEmitter:  broadcasts.NewEndpointsEmitter(),
+		attendantsEmitter: broadcasts.NewAttendantsEmitter(),
+	}
+}
+
+// Has returns the endpoint for ref, if the peer currently holds one.
+func (m *Manager) Has(ref refs.FeedRef) (muxrpc.Endpoint, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	edp, has := m.endpoints[ref.Ref()]
+	if !has {
+		return nil, false
+	}
+	return edp.Endpoint, true
+}
+
+// AsList returns the feed references of every currently connected peer.
+func (m *Manager) AsList() []refs.FeedRef {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lst := make([]refs.FeedRef, 0, len(m.endpoints))
+	for _, edp := range m.endpoints {
+		lst = append(lst, edp.Ref)
+	}
+	return lst
+}
+
+// Register adds ref's endpoint to the set of connected peers and notifies
+// subscribers of the change.
+func (m *Manager) Register(ref refs.FeedRef, edp muxrpc.Endpoint) {
+	m.mu.Lock()
+	m.endpoints[ref.Ref()] = Endpoint{Ref: ref, Endpoint: edp}
+	m.mu.Unlock()
+
+	m.endpointsEmitter.Emit()
+	m.attendantsEmitter.Emit()
+}
+
+// Remove drops ref from the set of connected peers and notifies subscribers
+// of the change.
+func (m *Manager) Remove(ref refs.FeedRef) {
+	m.mu.Lock()
+	delete(m.endpoints, ref.Ref())
+	m.mu.Unlock()
+
+	m.endpointsEmitter.Emit()
+	m.attendantsEmitter.Emit()
+}
+
+// EndpointsEmitter returns the broadcast that fires whenever the set of
+// connected endpoints changes.
+func (m *Manager) EndpointsEmitter() *broadcasts.EndpointsEmitter {
+	return m.endpointsEmitter
+}
+
+// AttendantsEmitter returns the broadcast that fires whenever the set of
+// attendants (connected peers visible to the room UI) changes.
+func (m *Manager) AttendantsEmitter() *broadcasts.AttendantsEmitter {
+	return m.attendantsEmitter
+}
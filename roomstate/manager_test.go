@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: MIT
+
+package roomstate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.cryptoscope.co/muxrpc/v2"
+	refs "go.mindeco.de/ssb-refs"
+)
+
+func TestManagerRegisterRemove(t *testing.T) {
+	r := require.New(t)
+
+	m := NewManager()
+
+	alice := refs.FeedRef{}
+
+	_, has := m.Has(alice)
+	r.False(has, "should not have an endpoint before Register")
+	r.Len(m.AsList(), 0)
+
+	var edp muxrpc.Endpoint
+	m.Register(alice, edp)
+
+	got, has := m.Has(alice)
+	r.True(has)
+	r.Equal(edp, got)
+	r.Len(m.AsList(), 1)
+
+	m.Remove(alice)
+
+	_, has = m.Has(alice)
+	r.False(has, "should not have an endpoint after Remove")
+	r.Len(m.AsList(), 0)
+}
+
+func TestManagerEmitsOnChange(t *testing.T) {
+	r := require.New(t)
+
+	m := NewManager()
+	ch, cancel := m.EndpointsEmitter().Subscribe()
+	defer cancel()
+
+	alice := refs.FeedRef{}
+	m.Register(alice, nil)
+
+	select {
+	case <-ch:
+	default:
+		r.Fail("expected a tick after Register")
+	}
+}
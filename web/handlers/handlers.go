@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: MIT
+
+// Package handlers serves the room's HTTP admin dashboard.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	kitlog "github.com/go-kit/kit/log"
+
+	"github.com/ssb-ngi-pointer/go-ssb-room/roomstate"
+)
+
+// Handlers serves the room's HTTP admin dashboard.
+type Handlers struct {
+	logger kitlog.Logger
+
+	// state is shared with the muxrpc tunnel plugin, so the dashboard can
+	// render connected peers live instead of keeping its own copy of that
+	// bookkeeping.
+	state *roomstate.Manager
+}
+
+// New constructs the HTTP admin dashboard handlers.
+func New(logger kitlog.Logger, state *roomstate.Manager) *Handlers {
+	return &Handlers{
+		logger: logger,
+		state:  state,
+	}
+}
+
+func (h *Handlers) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.URL.Path {
+	case "/admin/attendants":
+		h.serveAttendants(w, req)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// serveAttendants renders the list of currently connected peers.
+func (h *Handlers) serveAttendants(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.state.AsList())
+}